@@ -18,16 +18,22 @@ package secret
 
 import (
 	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/sha256"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/hex"
+	"encoding/pem"
 	"errors"
 	"fmt"
 	"math/big"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -38,6 +44,7 @@ import (
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/util/certs"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	bootstrapv1 "github.com/cluster-api-provider-k3s/cluster-api-k3s/bootstrap/api/v1beta1"
 )
@@ -46,8 +53,38 @@ const (
 	rootOwnerValue = "root:root"
 
 	DefaultCertificatesDir = "/var/lib/rancher/k3s/server/tls"
+
+	// NotAfterAnnotation records a certificate's expiry on its Secret; Lookup reads it back
+	// into Certificate.NotAfter, which EnsureRotated uses to decide when to roll a CA.
+	NotAfterAnnotation = "k3s.controlplane.cluster.x-k8s.io/certificate-not-after"
+
+	// RefreshPercentAnnotation records the fraction of validity, counted from NotBefore,
+	// after which a CA is eligible for rotation.
+	RefreshPercentAnnotation = "k3s.controlplane.cluster.x-k8s.io/certificate-refresh-percent"
+
+	// KeyAlgorithmAnnotation records the Algorithm a generated certificate's key was
+	// created with, so Lookup can restore it and subsequent rotations keep using the same
+	// algorithm even if a different KeyConfig is passed in later.
+	KeyAlgorithmAnnotation = "k3s.controlplane.cluster.x-k8s.io/key-algorithm"
+
+	// DefaultCARefreshPercent is the fraction of a CA's validity period after which
+	// EnsureRotated will generate and roll out a replacement signer.
+	DefaultCARefreshPercent = 80
+
+	// caBundleDataName is the Secret data key holding the additive union of every CA
+	// certificate currently trusted for a given Purpose.
+	caBundleDataName = "ca-bundle.crt"
+
+	// CertificatesFinalizer is set on generated certificate Secrets by SaveGenerated so
+	// Delete can clean them up even when the owning Cluster is force-deleted and its
+	// OwnerReference-driven garbage collection never runs.
+	CertificatesFinalizer = "k3s.controlplane.cluster.x-k8s.io/certificates"
 )
 
+// SecretCache is an informer-backed client.Reader, such as (ctrl.Manager).GetCache(),
+// used by LookupCached to avoid a live apiserver Get per certificate per reconcile.
+type SecretCache = client.Reader
+
 var (
 	// ErrMissingCertificate is an error indicating a certificate is entirely missing.
 	ErrMissingCertificate = errors.New("missing certificate")
@@ -61,11 +98,55 @@ var (
 	ErrMissingData = errors.New("missing data")
 )
 
+// Algorithm identifies a private key algorithm usable for generated certificates and
+// service account keys.
+type Algorithm string
+
+const (
+	RSA2048   Algorithm = "RSA2048"
+	RSA4096   Algorithm = "RSA4096"
+	ECDSAP256 Algorithm = "ECDSAP256"
+	Ed25519   Algorithm = "Ed25519"
+)
+
+// KeyConfig controls the key algorithm and certificate lifetimes used when generating new
+// certificates. The zero value is RSA2048 keys, a 10 year CA, and a 7 day leaf certificate:
+// leaves are short-lived by default since EnsureRotated reissues them off their CA every
+// time it rotates.
+type KeyConfig struct {
+	Algorithm    Algorithm
+	CAValidity   time.Duration
+	LeafValidity time.Duration
+}
+
+func (k KeyConfig) algorithm() Algorithm {
+	if k.Algorithm == "" {
+		return RSA2048
+	}
+	return k.Algorithm
+}
+
+func (k KeyConfig) caValidity() time.Duration {
+	if k.CAValidity == 0 {
+		return time.Hour * 24 * 365 * 10
+	}
+	return k.CAValidity
+}
+
+func (k KeyConfig) leafValidity() time.Duration {
+	if k.LeafValidity == 0 {
+		return time.Hour * 24 * 7
+	}
+	return k.LeafValidity
+}
+
 // Certificates are the certificates necessary to bootstrap a cluster.
 type Certificates []*Certificate
 
-// NewCertificatesForInitialControlPlane returns a list of certificates configured for a control plane node.
-func NewCertificatesForInitialControlPlane() Certificates {
+// NewCertificatesForInitialControlPlane returns a list of certificates configured for a
+// control plane node. Any purpose listed in externalCAs is marked External, so CAPI only
+// looks up its certificate and never generates or rotates it.
+func NewCertificatesForInitialControlPlane(externalCAs ...Purpose) Certificates {
 	certificatesDir := DefaultCertificatesDir
 
 	certificates := Certificates{
@@ -79,11 +160,91 @@ func NewCertificatesForInitialControlPlane() Certificates {
 			CertFile: filepath.Join(certificatesDir, "client-ca.crt"),
 			KeyFile:  filepath.Join(certificatesDir, "client-ca.key"),
 		},
+		&Certificate{
+			Purpose:  EtcdCA,
+			CertFile: filepath.Join(certificatesDir, "etcd", "server-ca.crt"),
+			KeyFile:  filepath.Join(certificatesDir, "etcd", "server-ca.key"),
+		},
+	}
+
+	for _, purpose := range externalCAs {
+		if certificate := certificates.GetByPurpose(purpose); certificate != nil {
+			certificate.External = true
+		}
 	}
 
 	return certificates
 }
 
+// NewCertificatesForInitialControlPlaneWithExternalCA returns the same certificates as
+// NewCertificatesForInitialControlPlane, with every purpose in purposes marked as an
+// externally-provided CA.
+func NewCertificatesForInitialControlPlaneWithExternalCA(purposes []Purpose) Certificates {
+	return NewCertificatesForInitialControlPlane(purposes...)
+}
+
+// NewCertificatesForJoiningControlPlane returns the CA certificates a joining control
+// plane node must trust before `k3s server` starts: the cluster CA and client CA, both
+// marked External since only the certificate, not the key, is needed.
+func NewCertificatesForJoiningControlPlane() Certificates {
+	certificatesDir := DefaultCertificatesDir
+
+	return Certificates{
+		&Certificate{
+			Purpose:  ClusterCA,
+			External: true,
+			CertFile: filepath.Join(certificatesDir, "server-ca.crt"),
+		},
+		&Certificate{
+			Purpose:  ClientClusterCA,
+			External: true,
+			CertFile: filepath.Join(certificatesDir, "client-ca.crt"),
+		},
+	}
+}
+
+// NewCertificatesForWorker returns the single CA certificate an agent must trust before
+// `k3s agent` starts, to be written to caCertPath (DefaultCertificatesDir's
+// "server-ca.crt" if caCertPath is empty).
+func NewCertificatesForWorker(caCertPath string) Certificates {
+	if caCertPath == "" {
+		caCertPath = filepath.Join(DefaultCertificatesDir, "server-ca.crt")
+	}
+
+	return Certificates{
+		&Certificate{
+			Purpose:  ClusterCA,
+			External: true,
+			CertFile: caCertPath,
+		},
+	}
+}
+
+// NewCertificatesForEtcdClient returns the etcd CA, marked External so Generate fails fast
+// instead of minting a substitute if Lookup hasn't found it yet, and an APIServerEtcdClient
+// leaf certificate signed by that CA, for reconcilers that dial a workload cluster's etcd
+// members directly. No such reconciler exists in this package yet; wiring it into etcd
+// membership management is out of scope here.
+func NewCertificatesForEtcdClient(certificatesDir string) Certificates {
+	if certificatesDir == "" {
+		certificatesDir = DefaultCertificatesDir
+	}
+
+	return Certificates{
+		&Certificate{
+			Purpose:  EtcdCA,
+			External: true,
+			CertFile: filepath.Join(certificatesDir, "etcd", "server-ca.crt"),
+			KeyFile:  filepath.Join(certificatesDir, "etcd", "server-ca.key"),
+		},
+		&Certificate{
+			Purpose:  APIServerEtcdClient,
+			CertFile: filepath.Join(certificatesDir, "etcd", "client.crt"),
+			KeyFile:  filepath.Join(certificatesDir, "etcd", "client.key"),
+		},
+	}
+}
+
 // GetByPurpose returns a certificate by the given name.
 // This could be removed if we use a map instead of a slice to hold certificates, however other code becomes more complex.
 func (c Certificates) GetByPurpose(purpose Purpose) *Certificate {
@@ -96,7 +257,7 @@ func (c Certificates) GetByPurpose(purpose Purpose) *Certificate {
 }
 
 // Lookup looks up each certificate from secrets and populates the certificate with the secret data.
-func (c Certificates) Lookup(ctx context.Context, ctrlclient client.Client, clusterName client.ObjectKey) error {
+func (c Certificates) Lookup(ctx context.Context, ctrlclient client.Reader, clusterName client.ObjectKey) error {
 	// Look up each certificate as a secret and populate the certificate/key
 	for _, certificate := range c {
 		s := &corev1.Secret{}
@@ -119,10 +280,74 @@ func (c Certificates) Lookup(ctx context.Context, ctrlclient client.Client, clus
 			return err
 		}
 		certificate.KeyPair = kp
+		if notAfter := notAfterFromAnnotations(s); !notAfter.IsZero() {
+			certificate.NotAfter = notAfter
+		} else if notAfter, err := certNotAfter(kp.Cert); err == nil {
+			certificate.NotAfter = notAfter
+		}
+		if alg, ok := s.Annotations[KeyAlgorithmAnnotation]; ok {
+			certificate.KeyConfig.Algorithm = Algorithm(alg)
+		}
+
+		if isCAPurpose(certificate.Purpose) {
+			if err := certificate.lookupBundle(ctx, ctrlclient, clusterName); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// notAfterFromAnnotations reads NotAfterAnnotation off a certificate Secret, returning the
+// zero time if it is absent or malformed. Lookup falls back to parsing the certificate
+// itself when this returns the zero time, so pre-existing Secrets remain rotation-eligible.
+func notAfterFromAnnotations(s *corev1.Secret) time.Time {
+	raw, ok := s.Annotations[NotAfterAnnotation]
+	if !ok {
+		return time.Time{}
+	}
+	notAfter, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return time.Time{}
+	}
+	return notAfter
+}
+
+// LookupCached is Lookup's cache-backed counterpart, resolving every Secret from cache
+// instead of issuing a live apiserver Get.
+func (c Certificates) LookupCached(ctx context.Context, cache SecretCache, clusterName client.ObjectKey) error {
+	return c.Lookup(ctx, cache, clusterName)
+}
+
+// lookupBundle populates Bundle from the companion "-ca-bundle" Secret for this
+// certificate's Purpose, if one has been published yet.
+func (c *Certificate) lookupBundle(ctx context.Context, ctrlclient client.Reader, clusterName client.ObjectKey) error {
+	s := &corev1.Secret{}
+	if err := ctrlclient.Get(ctx, caBundleKey(clusterName, c.Purpose), s); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
 	}
+	c.Bundle = s.Data[caBundleDataName]
 	return nil
 }
 
+// caBundleKey returns the object key of the companion Secret that holds the additive
+// union of every CA certificate currently trusted for purpose.
+func caBundleKey(clusterName client.ObjectKey, purpose Purpose) client.ObjectKey {
+	return client.ObjectKey{
+		Name:      Name(clusterName.Name, purpose) + "-ca-bundle",
+		Namespace: clusterName.Namespace,
+	}
+}
+
+// isCAPurpose reports whether purpose identifies a signing CA, as opposed to a leaf
+// certificate or key pair that is reissued by its CA rather than rotated directly.
+func isCAPurpose(purpose Purpose) bool {
+	return purpose != ServiceAccount && purpose != APIServerEtcdClient
+}
+
 // EnsureAllExist ensure that there is some data present for every certificate.
 func (c Certificates) EnsureAllExist() error {
 	for _, certificate := range c {
@@ -136,31 +361,163 @@ func (c Certificates) EnsureAllExist() error {
 			if len(certificate.KeyPair.Key) == 0 {
 				return fmt.Errorf("for certificate %s: %w", certificate.Purpose, ErrMissingKey)
 			}
+			continue
+		}
+
+		if isCAPurpose(certificate.Purpose) {
+			if err := validateExternalCACert(certificate.KeyPair.Cert); err != nil {
+				return fmt.Errorf("for certificate %s: %w", certificate.Purpose, err)
+			}
 		}
 	}
 	return nil
 }
 
-// Generate will generate any certificates that do not have KeyPair data.
-func (c Certificates) Generate() error {
+// validateExternalCACert checks that an operator-supplied external CA certificate is
+// usable as a signer: it parses, is marked as a CA, and has not already expired.
+func validateExternalCACert(certPEM []byte) error {
+	certificates, err := cert.ParseCertsPEM(certPEM)
+	if err != nil {
+		return fmt.Errorf("unable to parse certificate: %w", err)
+	}
+	if len(certificates) == 0 {
+		return ErrMissingCrt
+	}
+
+	crt := certificates[0]
+	if !crt.IsCA {
+		return errors.New("certificate is not a CA")
+	}
+	if !crt.BasicConstraintsValid {
+		return errors.New("certificate is missing basic constraints")
+	}
+	if time.Now().UTC().After(crt.NotAfter) {
+		return fmt.Errorf("certificate expired at %s", crt.NotAfter)
+	}
+	return nil
+}
+
+// Validate checks that every loaded CA is usable as a signer (IsCA, KeyUsageCertSign) and
+// that every leaf certificate chains to its configured CA, generated or external alike.
+func (c Certificates) Validate() error {
 	for _, certificate := range c {
-		if certificate.KeyPair == nil {
-			err := certificate.Generate()
-			if err != nil {
+		if certificate.KeyPair == nil || len(certificate.KeyPair.Cert) == 0 {
+			continue
+		}
+		if certificate.Purpose == ServiceAccount {
+			// A service account key pair holds a public key, not a certificate.
+			continue
+		}
+
+		certificates, err := cert.ParseCertsPEM(certificate.KeyPair.Cert)
+		if err != nil || len(certificates) == 0 {
+			return fmt.Errorf("for certificate %s: unable to parse: %w", certificate.Purpose, err)
+		}
+		crt := certificates[0]
+
+		if isCAPurpose(certificate.Purpose) {
+			if !crt.IsCA {
+				return fmt.Errorf("for certificate %s: not a CA", certificate.Purpose)
+			}
+			if crt.KeyUsage&x509.KeyUsageCertSign == 0 {
+				return fmt.Errorf("for certificate %s: missing KeyUsageCertSign", certificate.Purpose)
+			}
+			continue
+		}
+
+		if err := c.verifyLeafChainsToCA(certificate.Purpose, crt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verifyLeafChainsToCA checks that a non-CA certificate was signed by its configured CA.
+// ServiceAccount key pairs have no CA to chain to and are skipped.
+func (c Certificates) verifyLeafChainsToCA(purpose Purpose, leaf *x509.Certificate) error {
+	if purpose == ServiceAccount {
+		return nil
+	}
+
+	caPurpose := leafCAPurpose(purpose)
+	ca := c.GetByPurpose(caPurpose)
+	if ca == nil || ca.KeyPair == nil {
+		return fmt.Errorf("for certificate %s: signing CA %s not loaded", purpose, caPurpose)
+	}
+
+	caCerts, err := cert.ParseCertsPEM(ca.KeyPair.Cert)
+	if err != nil || len(caCerts) == 0 {
+		return fmt.Errorf("for certificate %s: unable to parse signing CA %s: %w", purpose, caPurpose, err)
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(caCerts[0])
+	if _, err := leaf.Verify(x509.VerifyOptions{Roots: pool, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny}}); err != nil {
+		return fmt.Errorf("for certificate %s: does not chain to %s: %w", purpose, caPurpose, err)
+	}
+	return nil
+}
+
+// leafCAPurpose returns the Purpose of the CA that signs the given leaf certificate Purpose.
+func leafCAPurpose(purpose Purpose) Purpose {
+	if purpose == APIServerEtcdClient {
+		return EtcdCA
+	}
+	return purpose
+}
+
+// Generate will generate any certificates that do not have KeyPair data, using cfg's
+// algorithm and validity periods. cfg is stamped onto each certificate that doesn't
+// already have a KeyConfig; Lookup restores KeyConfig.Algorithm from
+// KeyAlgorithmAnnotation, so a certificate generated once keeps using the same algorithm
+// across future reconciles and rotations even if a different cfg is passed in later.
+func (c Certificates) Generate(cfg KeyConfig) error {
+	for _, certificate := range c {
+		if certificate.KeyConfig == (KeyConfig{}) {
+			certificate.KeyConfig = cfg
+		}
+	}
+
+	etcdCA := c.GetByPurpose(EtcdCA)
+
+	for _, certificate := range c {
+		if certificate.KeyPair != nil {
+			continue
+		}
+
+		if certificate.External {
+			// External CAs are supplied by the operator; Lookup is responsible for
+			// surfacing a clear error if the Secret holding the certificate is missing.
+			continue
+		}
+
+		if certificate.Purpose == APIServerEtcdClient {
+			if etcdCA == nil || etcdCA.KeyPair == nil {
+				return fmt.Errorf("cannot generate %s: %w", certificate.Purpose, ErrMissingCertificate)
+			}
+			if err := certificate.GenerateSignedBy(etcdCA.KeyPair); err != nil {
 				return err
 			}
+			continue
+		}
+
+		if err := certificate.Generate(); err != nil {
+			return err
 		}
 	}
 	return nil
 }
 
 // SaveGenerated will save any certificates that have been generated as Kubernetes secrets.
+// Each Secret gets CertificatesFinalizer so Delete can still clean it up even if the
+// owning Cluster is force-deleted and OwnerReference garbage collection never fires.
 func (c Certificates) SaveGenerated(ctx context.Context, ctrlclient client.Client, clusterName client.ObjectKey, owner metav1.OwnerReference) error {
 	for _, certificate := range c {
 		if !certificate.Generated {
 			continue
 		}
 		s := certificate.AsSecret(clusterName, owner)
+		controllerutil.AddFinalizer(s, CertificatesFinalizer)
 		if err := ctrlclient.Create(ctx, s); err != nil {
 			return err
 		}
@@ -168,15 +525,66 @@ func (c Certificates) SaveGenerated(ctx context.Context, ctrlclient client.Clien
 	return nil
 }
 
+// Delete removes every signer and CA bundle Secret generated for this certificate set,
+// clearing CertificatesFinalizer first so deletion completes even on a force-deleted
+// Cluster. External certificates are skipped: their Secret (e.g. the shared cluster CA
+// referenced by a single joining or worker node's Certificates) is not owned by this call.
+func (c Certificates) Delete(ctx context.Context, ctrlclient client.Client, clusterName client.ObjectKey) error {
+	for _, certificate := range c {
+		if certificate.External {
+			continue
+		}
+
+		key := client.ObjectKey{
+			Name:      Name(clusterName.Name, certificate.Purpose),
+			Namespace: clusterName.Namespace,
+		}
+		if err := deleteSecret(ctx, ctrlclient, key); err != nil {
+			return err
+		}
+
+		if isCAPurpose(certificate.Purpose) {
+			if err := deleteSecret(ctx, ctrlclient, caBundleKey(clusterName, certificate.Purpose)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// deleteSecret clears CertificatesFinalizer from the Secret at key, if present, then
+// deletes it. A missing Secret is not an error.
+func deleteSecret(ctx context.Context, ctrlclient client.Client, key client.ObjectKey) error {
+	s := &corev1.Secret{}
+	if err := ctrlclient.Get(ctx, key, s); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	if controllerutil.ContainsFinalizer(s, CertificatesFinalizer) {
+		controllerutil.RemoveFinalizer(s, CertificatesFinalizer)
+		if err := ctrlclient.Update(ctx, s); err != nil {
+			return err
+		}
+	}
+
+	if err := ctrlclient.Delete(ctx, s); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
 // LookupOrGenerate is a convenience function that wraps cluster bootstrap certificate behavior.
-func (c Certificates) LookupOrGenerate(ctx context.Context, ctrlclient client.Client, clusterName client.ObjectKey, owner metav1.OwnerReference) error {
+func (c Certificates) LookupOrGenerate(ctx context.Context, ctrlclient client.Client, clusterName client.ObjectKey, owner metav1.OwnerReference, cfg KeyConfig) error {
 	// Find the certificates that exist
 	if err := c.Lookup(ctx, ctrlclient, clusterName); err != nil {
 		return err
 	}
 
 	// Generate the certificates that don't exist
-	if err := c.Generate(); err != nil {
+	if err := c.Generate(cfg); err != nil {
 		return err
 	}
 
@@ -188,12 +596,70 @@ func (c Certificates) LookupOrGenerate(ctx context.Context, ctrlclient client.Cl
 	return nil
 }
 
+// EnsureRotated rotates any CA past its refresh window onto a fresh signing keypair and
+// reissues every leaf that chains to it, so nothing keeps trusting a signer
+// pruneExpiredCerts will eventually drop.
+func (c Certificates) EnsureRotated(ctx context.Context, ctrlclient client.Client, clusterName client.ObjectKey, owner metav1.OwnerReference) error {
+	for _, certificate := range c {
+		if certificate.External || !isCAPurpose(certificate.Purpose) {
+			continue
+		}
+		if !certificate.needsRotation() {
+			continue
+		}
+		if err := certificate.rotate(ctx, ctrlclient, clusterName, owner); err != nil {
+			return fmt.Errorf("rotating %s: %w", certificate.Purpose, err)
+		}
+		if err := c.reissueLeavesOf(ctx, ctrlclient, clusterName, certificate); err != nil {
+			return fmt.Errorf("reissuing leaves of %s: %w", certificate.Purpose, err)
+		}
+	}
+	return nil
+}
+
+// reissueLeavesOf regenerates and persists every certificate in c signed by ca (per
+// leafCAPurpose), skipping any that haven't been generated yet since Generate will issue
+// them off ca's current keypair anyway.
+func (c Certificates) reissueLeavesOf(ctx context.Context, ctrlclient client.Client, clusterName client.ObjectKey, ca *Certificate) error {
+	for _, certificate := range c {
+		if certificate.Purpose == ca.Purpose || leafCAPurpose(certificate.Purpose) != ca.Purpose {
+			continue
+		}
+		if certificate.KeyPair == nil {
+			continue
+		}
+		if err := certificate.GenerateSignedBy(ca.KeyPair); err != nil {
+			return fmt.Errorf("reissuing %s: %w", certificate.Purpose, err)
+		}
+		if err := certificate.saveRotated(ctx, ctrlclient, clusterName); err != nil {
+			return fmt.Errorf("persisting reissued %s: %w", certificate.Purpose, err)
+		}
+	}
+	return nil
+}
+
 // Certificate represents a single certificate CA.
 type Certificate struct {
-	Generated         bool
-	External          bool
-	Purpose           Purpose
-	KeyPair           *certs.KeyPair
+	Generated bool
+	External  bool
+	Purpose   Purpose
+	KeyPair   *certs.KeyPair
+
+	// KeyConfig is the algorithm and validity this certificate was (or will be) generated
+	// with. Stamped by Certificates.Generate on first generation and restored by Lookup
+	// from KeyAlgorithmAnnotation, so rotations reuse the same algorithm across reconciles.
+	KeyConfig KeyConfig
+
+	// NotAfter is KeyPair's expiry; it drives EnsureRotated's refresh-window check.
+	NotAfter time.Time
+
+	// Previous is the signing keypair this CA was rotated from, if any.
+	Previous *certs.KeyPair
+
+	// Bundle is the additive union of every CA certificate currently trusted for this
+	// Purpose; empty until the CA's first rotation.
+	Bundle []byte
+
 	CertFile, KeyFile string
 }
 
@@ -233,8 +699,19 @@ func (c *Certificate) AsSecret(clusterName client.ObjectKey, owner metav1.OwnerR
 		Type: clusterv1.ClusterSecretType,
 	}
 
+	if !c.NotAfter.IsZero() {
+		s.Annotations = map[string]string{
+			NotAfterAnnotation:       c.NotAfter.Format(time.RFC3339),
+			RefreshPercentAnnotation: strconv.Itoa(DefaultCARefreshPercent),
+		}
+	}
+
 	if c.Generated {
 		s.OwnerReferences = []metav1.OwnerReference{owner}
+		if s.Annotations == nil {
+			s.Annotations = map[string]string{}
+		}
+		s.Annotations[KeyAlgorithmAnnotation] = string(c.KeyConfig.algorithm())
 	}
 	return s
 }
@@ -242,12 +719,20 @@ func (c *Certificate) AsSecret(clusterName client.ObjectKey, owner metav1.OwnerR
 // AsFiles converts the certificate to a slice of Files that may have 0, 1 or 2 Files.
 func (c *Certificate) AsFiles() []bootstrapv1.File {
 	out := make([]bootstrapv1.File, 0)
-	if len(c.KeyPair.Cert) > 0 {
+
+	// Once a CA has been rotated at least once, Bundle holds the additive union of every
+	// trusted CA certificate; nodes must trust all of them, not just the active signer.
+	certPEM := c.KeyPair.Cert
+	if len(c.Bundle) > 0 {
+		certPEM = c.Bundle
+	}
+
+	if len(certPEM) > 0 {
 		out = append(out, bootstrapv1.File{
 			Path:        c.CertFile,
 			Owner:       rootOwnerValue,
 			Permissions: "0640",
-			Content:     string(c.KeyPair.Cert),
+			Content:     string(certPEM),
 		})
 	}
 	if len(c.KeyPair.Key) > 0 {
@@ -262,26 +747,362 @@ func (c *Certificate) AsFiles() []bootstrapv1.File {
 }
 
 func (c *Certificate) Generate() error {
-	// Do not generate the APIServerEtcdClient key pair. It is user supplied
-	if c.Purpose == APIServerEtcdClient {
-		return nil
-	}
-
 	generator := generateCACert
 	if c.Purpose == ServiceAccount {
 		generator = generateServiceAccountKeys
 	}
 
-	kp, err := generator()
+	kp, err := generator(c.KeyConfig)
 	if err != nil {
 		return err
 	}
 	c.KeyPair = kp
 	c.Generated = true
 
+	if notAfter, err := certNotAfter(kp.Cert); err == nil {
+		c.NotAfter = notAfter
+	}
+
 	return nil
 }
 
+// certNotAfter returns the NotAfter of the first certificate encoded in certPEM.
+func certNotAfter(certPEM []byte) (time.Time, error) {
+	certificates, err := cert.ParseCertsPEM(certPEM)
+	if err != nil || len(certificates) == 0 {
+		return time.Time{}, fmt.Errorf("unable to determine certificate expiry: %w", err)
+	}
+	return certificates[0].NotAfter, nil
+}
+
+// GenerateSignedBy generates a leaf key pair signed by the given CA key pair, rather than
+// self-signed. The leaf is always the apiserver's etcd client certificate (see
+// newSignedEtcdClientCert), whatever c.Purpose is.
+func (c *Certificate) GenerateSignedBy(ca *certs.KeyPair) error {
+	caCert, caKey, err := decodeCAKeyPair(ca)
+	if err != nil {
+		return fmt.Errorf("for certificate %s: %w", c.Purpose, err)
+	}
+
+	x509Cert, key, err := newSignedEtcdClientCert(caCert, caKey, c.KeyConfig)
+	if err != nil {
+		return err
+	}
+
+	keyPEM, err := encodePrivateKeyPEM(key)
+	if err != nil {
+		return err
+	}
+
+	c.KeyPair = &certs.KeyPair{
+		Cert: certs.EncodeCertPEM(x509Cert),
+		Key:  keyPEM,
+	}
+	c.Generated = true
+	c.NotAfter = x509Cert.NotAfter
+
+	return nil
+}
+
+// decodeCAKeyPair parses a PEM-encoded CA KeyPair back into an x509.Certificate and its
+// private key so it can be used to sign a leaf certificate, regardless of which
+// KeyConfig.Algorithm it was generated with.
+func decodeCAKeyPair(ca *certs.KeyPair) (*x509.Certificate, crypto.Signer, error) {
+	parsedCerts, err := cert.ParseCertsPEM(ca.Cert)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to parse CA certificate: %w", err)
+	}
+	if len(parsedCerts) == 0 {
+		return nil, nil, ErrMissingCrt
+	}
+
+	key, err := parsePrivateKeyPEM(ca.Key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to parse CA private key: %w", err)
+	}
+
+	return parsedCerts[0], key, nil
+}
+
+// newSignedEtcdClientCert creates the apiserver's etcd client certificate: a short-lived
+// leaf cert signed by the etcd CA, with ExtKeyUsageClientAuth and CN=kube-apiserver-etcd-client
+// so etcd can authenticate and authorize the apiserver via client cert auth.
+func newSignedEtcdClientCert(caCert *x509.Certificate, caKey crypto.Signer, cfg KeyConfig) (*x509.Certificate, crypto.Signer, error) {
+	key, err := newPrivateKey(cfg.algorithm())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now().UTC()
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate certificate serial number: %w", err)
+	}
+
+	tmpl := x509.Certificate{
+		SerialNumber: serial,
+		Subject: pkix.Name{
+			CommonName: "kube-apiserver-etcd-client",
+		},
+		NotBefore:             now.Add(time.Minute * -5),
+		NotAfter:              now.Add(cfg.leafValidity()),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+	}
+
+	b, err := x509.CreateCertificate(rand.Reader, &tmpl, caCert, key.Public(), caKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create etcd client certificate %+v: %w", tmpl, err)
+	}
+
+	c, err := x509.ParseCertificate(b)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return c, key, nil
+}
+
+// newPrivateKey generates a private key for the given algorithm. ECDSAP256 and Ed25519
+// use Go's stdlib curves directly; RSA2048 and RSA4096 use the module's historical key
+// sizes, matching what k3s's embedded etcd and kube-apiserver accept.
+func newPrivateKey(alg Algorithm) (crypto.Signer, error) {
+	switch alg {
+	case RSA2048:
+		return certs.NewPrivateKey()
+	case RSA4096:
+		return rsa.GenerateKey(rand.Reader, 4096)
+	case ECDSAP256:
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case Ed25519:
+		_, priv, err := ed25519.GenerateKey(rand.Reader)
+		return priv, err
+	default:
+		return nil, fmt.Errorf("unsupported key algorithm %q", alg)
+	}
+}
+
+// encodePrivateKeyPEM PEM-encodes key as a PKCS#8 private key. Unlike
+// certs.EncodePrivateKeyPEM, which only understands RSA, this handles any algorithm
+// KeyConfig supports.
+func encodePrivateKeyPEM(key crypto.Signer) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("unable to marshal private key: %w", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// encodePublicKeyPEM PEM-encodes the public half of key in PKIX form, for any algorithm
+// KeyConfig supports.
+func encodePublicKeyPEM(key crypto.Signer) ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		return nil, fmt.Errorf("unable to marshal public key: %w", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+}
+
+// parsePrivateKeyPEM parses a PEM-encoded private key, accepting both the legacy PKCS#1
+// RSA encoding and the generic PKCS#8 encoding produced by encodePrivateKeyPEM.
+func parsePrivateKeyPEM(keyPEM []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, ErrMissingKey
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse private key: %w", err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("private key of type %T is not a signer", key)
+	}
+	return signer, nil
+}
+
+// needsRotation reports whether this CA has elapsed DefaultCARefreshPercent of its
+// validity period and should be rotated.
+func (c *Certificate) needsRotation() bool {
+	if c.KeyPair == nil || c.NotAfter.IsZero() {
+		return false
+	}
+
+	certificates, err := cert.ParseCertsPEM(c.KeyPair.Cert)
+	if err != nil || len(certificates) == 0 {
+		return false
+	}
+
+	notBefore := certificates[0].NotBefore
+	validity := c.NotAfter.Sub(notBefore)
+	if validity <= 0 {
+		return false
+	}
+
+	refreshAt := notBefore.Add(time.Duration(float64(validity) * float64(DefaultCARefreshPercent) / 100))
+	return time.Now().UTC().After(refreshAt)
+}
+
+// rotate generates a new signing keypair for this CA, publishes it to the bundle Secret,
+// then flips the active signer and persists it, retaining the previous keypair on Previous.
+func (c *Certificate) rotate(ctx context.Context, ctrlclient client.Client, clusterName client.ObjectKey, owner metav1.OwnerReference) error {
+	newKP, err := generateCACert(c.KeyConfig)
+	if err != nil {
+		return err
+	}
+	notAfter, err := certNotAfter(newKP.Cert)
+	if err != nil {
+		return err
+	}
+
+	if err := c.publishBundle(ctx, ctrlclient, clusterName, newKP, owner); err != nil {
+		return err
+	}
+
+	c.Previous = c.KeyPair
+	c.KeyPair = newKP
+	c.NotAfter = notAfter
+	c.Generated = true
+
+	return c.saveRotated(ctx, ctrlclient, clusterName)
+}
+
+// publishBundle adds the current and incoming CA certificates to the companion
+// "-ca-bundle" Secret, prunes any CA that has already expired, and creates or updates the
+// Secret, setting owner on creation so it is garbage collected along with the Cluster.
+func (c *Certificate) publishBundle(ctx context.Context, ctrlclient client.Client, clusterName client.ObjectKey, newKP *certs.KeyPair, owner metav1.OwnerReference) error {
+	bundleKey := caBundleKey(clusterName, c.Purpose)
+
+	bundle := &corev1.Secret{}
+	create := false
+	if err := ctrlclient.Get(ctx, bundleKey, bundle); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		create = true
+		bundle = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      bundleKey.Name,
+				Namespace: bundleKey.Namespace,
+				Labels: map[string]string{
+					clusterv1.ClusterNameLabel: clusterName.Name,
+				},
+				OwnerReferences: []metav1.OwnerReference{owner},
+			},
+			Type: clusterv1.ClusterSecretType,
+		}
+	}
+
+	union, err := appendCertToBundle(bundle.Data[caBundleDataName], c.KeyPair.Cert)
+	if err != nil {
+		return err
+	}
+	union, err = appendCertToBundle(union, newKP.Cert)
+	if err != nil {
+		return err
+	}
+	union, err = pruneExpiredCerts(union)
+	if err != nil {
+		return err
+	}
+
+	if bundle.Data == nil {
+		bundle.Data = map[string][]byte{}
+	}
+	bundle.Data[caBundleDataName] = union
+	c.Bundle = union
+
+	if create {
+		return ctrlclient.Create(ctx, bundle)
+	}
+	return ctrlclient.Update(ctx, bundle)
+}
+
+// saveRotated persists freshly (re)generated certificate material and its new expiry
+// annotations to its existing Secret, whether c is a CA that just rotated or a leaf just
+// reissued against one.
+func (c *Certificate) saveRotated(ctx context.Context, ctrlclient client.Client, clusterName client.ObjectKey) error {
+	key := client.ObjectKey{
+		Name:      Name(clusterName.Name, c.Purpose),
+		Namespace: clusterName.Namespace,
+	}
+	s := &corev1.Secret{}
+	if err := ctrlclient.Get(ctx, key, s); err != nil {
+		return err
+	}
+
+	s.Data[TLSKeyDataName] = c.KeyPair.Key
+	s.Data[TLSCrtDataName] = c.KeyPair.Cert
+	if s.Annotations == nil {
+		s.Annotations = map[string]string{}
+	}
+	s.Annotations[NotAfterAnnotation] = c.NotAfter.Format(time.RFC3339)
+	s.Annotations[RefreshPercentAnnotation] = strconv.Itoa(DefaultCARefreshPercent)
+	s.Annotations[KeyAlgorithmAnnotation] = string(c.KeyConfig.algorithm())
+
+	return ctrlclient.Update(ctx, s)
+}
+
+// appendCertToBundle adds certPEM to bundle if an equivalent certificate (compared by
+// SubjectPublicKeyInfo hash) is not already present, returning the resulting bundle.
+func appendCertToBundle(bundle, certPEM []byte) ([]byte, error) {
+	existing, err := parseCertsPEMTolerant(bundle)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse existing CA bundle: %w", err)
+	}
+	incoming, err := cert.ParseCertsPEM(certPEM)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse certificate to add to bundle: %w", err)
+	}
+
+	seen := make(map[string]struct{}, len(existing))
+	out := append([]byte{}, bundle...)
+	for _, crt := range existing {
+		seen[hashCert(crt)] = struct{}{}
+	}
+	for _, crt := range incoming {
+		if _, ok := seen[hashCert(crt)]; ok {
+			continue
+		}
+		out = append(out, certs.EncodeCertPEM(crt)...)
+		seen[hashCert(crt)] = struct{}{}
+	}
+	return out, nil
+}
+
+// pruneExpiredCerts drops any certificate from bundle whose NotAfter has already passed.
+func pruneExpiredCerts(bundle []byte) ([]byte, error) {
+	certificates, err := parseCertsPEMTolerant(bundle)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse CA bundle: %w", err)
+	}
+
+	now := time.Now().UTC()
+	out := make([]byte, 0, len(bundle))
+	for _, crt := range certificates {
+		if crt.NotAfter.Before(now) {
+			continue
+		}
+		out = append(out, certs.EncodeCertPEM(crt)...)
+	}
+	return out, nil
+}
+
+// parseCertsPEMTolerant parses pemData as a sequence of PEM certificates, treating empty
+// input as zero certificates rather than an error.
+func parseCertsPEMTolerant(pemData []byte) ([]*x509.Certificate, error) {
+	if len(pemData) == 0 {
+		return nil, nil
+	}
+	return cert.ParseCertsPEM(pemData)
+}
+
 // AsFiles converts a slice of certificates into bootstrap files.
 func (c Certificates) AsFiles() []bootstrapv1.File {
 	clusterCA := c.GetByPurpose(ClusterCA)
@@ -328,40 +1149,56 @@ func secretToKeyPair(s *corev1.Secret) (*certs.KeyPair, error) {
 	}, nil
 }
 
-func generateCACert() (*certs.KeyPair, error) {
-	x509Cert, privKey, err := newCertificateAuthority()
+func generateCACert(cfg KeyConfig) (*certs.KeyPair, error) {
+	x509Cert, privKey, err := newCertificateAuthority(cfg)
+	if err != nil {
+		return nil, err
+	}
+	keyPEM, err := encodePrivateKeyPEM(privKey)
 	if err != nil {
 		return nil, err
 	}
 	return &certs.KeyPair{
 		Cert: certs.EncodeCertPEM(x509Cert),
-		Key:  certs.EncodePrivateKeyPEM(privKey),
+		Key:  keyPEM,
 	}, nil
 }
 
-func generateServiceAccountKeys() (*certs.KeyPair, error) {
-	saCreds, err := certs.NewPrivateKey()
+// generateServiceAccountKeys generates the service account signing key pair.
+// kube-apiserver's service account token signer only supports RSA and ECDSA keys, so
+// Ed25519 is rejected here even if it was requested cluster-wide via KeyConfig.
+func generateServiceAccountKeys(cfg KeyConfig) (*certs.KeyPair, error) {
+	alg := cfg.algorithm()
+	if alg != RSA2048 && alg != RSA4096 && alg != ECDSAP256 {
+		return nil, fmt.Errorf("key algorithm %q is not supported for service account tokens", alg)
+	}
+
+	saCreds, err := newPrivateKey(alg)
+	if err != nil {
+		return nil, err
+	}
+	saPub, err := encodePublicKeyPEM(saCreds)
 	if err != nil {
 		return nil, err
 	}
-	saPub, err := certs.EncodePublicKeyPEM(&saCreds.PublicKey)
+	saKey, err := encodePrivateKeyPEM(saCreds)
 	if err != nil {
 		return nil, err
 	}
 	return &certs.KeyPair{
 		Cert: saPub,
-		Key:  certs.EncodePrivateKeyPEM(saCreds),
+		Key:  saKey,
 	}, nil
 }
 
-// newCertificateAuthority creates new certificate and private key for the certificate authority.
-func newCertificateAuthority() (*x509.Certificate, *rsa.PrivateKey, error) {
-	key, err := certs.NewPrivateKey()
+// newCertificateAuthority creates a new certificate and private key for the certificate authority.
+func newCertificateAuthority(cfg KeyConfig) (*x509.Certificate, crypto.Signer, error) {
+	key, err := newPrivateKey(cfg.algorithm())
 	if err != nil {
 		return nil, nil, err
 	}
 
-	c, err := newSelfSignedCACert(key)
+	c, err := newSelfSignedCACert(key, cfg.caValidity())
 	if err != nil {
 		return nil, nil, err
 	}
@@ -369,8 +1206,9 @@ func newCertificateAuthority() (*x509.Certificate, *rsa.PrivateKey, error) {
 	return c, key, nil
 }
 
-// newSelfSignedCACert creates a CA certificate.
-func newSelfSignedCACert(key *rsa.PrivateKey) (*x509.Certificate, error) {
+// newSelfSignedCACert creates a CA certificate valid for validity, starting 5 minutes in
+// the past to tolerate clock skew.
+func newSelfSignedCACert(key crypto.Signer, validity time.Duration) (*x509.Certificate, error) {
 	cfg := certs.Config{
 		CommonName: "kubernetes",
 	}
@@ -384,7 +1222,7 @@ func newSelfSignedCACert(key *rsa.PrivateKey) (*x509.Certificate, error) {
 			Organization: cfg.Organization,
 		},
 		NotBefore:             now.Add(time.Minute * -5),
-		NotAfter:              now.Add(time.Hour * 24 * 365 * 10), // 10 years
+		NotAfter:              now.Add(validity),
 		KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
 		MaxPathLenZero:        true,
 		BasicConstraintsValid: true,
@@ -0,0 +1,335 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secret
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/util/cert"
+	"sigs.k8s.io/cluster-api/util/certs"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGenerateCACertAlgorithms(t *testing.T) {
+	for _, alg := range []Algorithm{RSA2048, RSA4096, ECDSAP256, Ed25519} {
+		t.Run(string(alg), func(t *testing.T) {
+			kp, err := generateCACert(KeyConfig{Algorithm: alg})
+			if err != nil {
+				t.Fatalf("generateCACert(%s): %v", alg, err)
+			}
+
+			certificates, err := cert.ParseCertsPEM(kp.Cert)
+			if err != nil || len(certificates) == 0 {
+				t.Fatalf("unable to parse generated certificate: %v", err)
+			}
+			crt := certificates[0]
+			if !crt.IsCA || crt.KeyUsage&x509.KeyUsageCertSign == 0 {
+				t.Fatalf("generated certificate for %s is not a usable CA", alg)
+			}
+
+			key, err := parsePrivateKeyPEM(kp.Key)
+			if err != nil {
+				t.Fatalf("unable to parse generated key: %v", err)
+			}
+			if !publicKeysEqual(key.Public(), crt.PublicKey) {
+				t.Fatalf("generated key does not match certificate public key for %s", alg)
+			}
+		})
+	}
+}
+
+func TestGenerateServiceAccountKeysRejectsEd25519(t *testing.T) {
+	if _, err := generateServiceAccountKeys(KeyConfig{Algorithm: Ed25519}); err == nil {
+		t.Fatal("expected an error generating service account keys with Ed25519")
+	}
+}
+
+func TestGenerateServiceAccountKeysSupportedAlgorithms(t *testing.T) {
+	for _, alg := range []Algorithm{RSA2048, RSA4096, ECDSAP256} {
+		t.Run(string(alg), func(t *testing.T) {
+			kp, err := generateServiceAccountKeys(KeyConfig{Algorithm: alg})
+			if err != nil {
+				t.Fatalf("generateServiceAccountKeys(%s): %v", alg, err)
+			}
+			if len(kp.Cert) == 0 || len(kp.Key) == 0 {
+				t.Fatalf("generateServiceAccountKeys(%s) returned incomplete key pair", alg)
+			}
+		})
+	}
+}
+
+func publicKeysEqual(a, b crypto.PublicKey) bool {
+	type equaler interface {
+		Equal(x crypto.PublicKey) bool
+	}
+	eq, ok := a.(equaler)
+	return ok && eq.Equal(b)
+}
+
+// TestValidateExternalCACertAcceptsUnconstrainedCA guards against regressing the fix for
+// chunk0-3: a vanilla CA certificate, as produced by openssl/cfssl/cert-manager without an
+// explicit pathLenConstraint, parses with MaxPathLen == -1, MaxPathLenZero == false. That
+// is the normal, unconstrained shape and must not be rejected.
+func TestValidateExternalCACertAcceptsUnconstrainedCA(t *testing.T) {
+	key, err := newPrivateKey(RSA2048)
+	if err != nil {
+		t.Fatalf("newPrivateKey: %v", err)
+	}
+
+	now := time.Now().UTC()
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, key.Public(), key)
+	if err != nil {
+		t.Fatalf("CreateCertificate: %v", err)
+	}
+
+	parsed, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("ParseCertificate: %v", err)
+	}
+	if parsed.MaxPathLenZero || parsed.MaxPathLen != -1 {
+		t.Fatalf("expected an unconstrained CA (MaxPathLen=-1), got MaxPathLen=%d MaxPathLenZero=%v", parsed.MaxPathLen, parsed.MaxPathLenZero)
+	}
+
+	certPEM := certs.EncodeCertPEM(parsed)
+	if err := validateExternalCACert(certPEM); err != nil {
+		t.Fatalf("validateExternalCACert rejected an unconstrained CA: %v", err)
+	}
+}
+
+func TestLookupFallsBackToCertNotAfter(t *testing.T) {
+	kp, err := generateCACert(KeyConfig{})
+	if err != nil {
+		t.Fatalf("generateCACert: %v", err)
+	}
+	wantNotAfter, err := certNotAfter(kp.Cert)
+	if err != nil {
+		t.Fatalf("certNotAfter: %v", err)
+	}
+
+	clusterName := client.ObjectKey{Namespace: "default", Name: "test-cluster"}
+	s := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      Name(clusterName.Name, ClusterCA),
+			Namespace: clusterName.Namespace,
+		},
+		Data: map[string][]byte{
+			TLSCrtDataName: kp.Cert,
+			TLSKeyDataName: kp.Key,
+		},
+	}
+
+	sch := runtime.NewScheme()
+	if err := scheme.AddToScheme(sch); err != nil {
+		t.Fatalf("AddToScheme: %v", err)
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(sch).WithObjects(s).Build()
+
+	allCerts := Certificates{{Purpose: ClusterCA}}
+	if err := allCerts.Lookup(context.Background(), fakeClient, clusterName); err != nil {
+		t.Fatalf("Lookup: %v", err)
+	}
+
+	got := allCerts.GetByPurpose(ClusterCA)
+	if got.NotAfter.IsZero() {
+		t.Fatal("Lookup left NotAfter as the zero time for a Secret with no NotAfterAnnotation")
+	}
+	if !got.NotAfter.Equal(wantNotAfter) {
+		t.Fatalf("NotAfter = %v, want %v", got.NotAfter, wantNotAfter)
+	}
+}
+
+// TestNewCertificatesForEtcdClientGenerateFailsWithoutLookup guards against regressing the
+// fix for chunk0-1: EtcdCA is External, so Generate must refuse to mint a substitute etcd
+// CA when the real one hasn't been found by Lookup yet.
+func TestNewCertificatesForEtcdClientGenerateFailsWithoutLookup(t *testing.T) {
+	allCerts := NewCertificatesForEtcdClient("")
+	if err := allCerts.Generate(KeyConfig{}); err == nil {
+		t.Fatal("expected Generate to fail without a looked-up etcd CA")
+	}
+	if kp := allCerts.GetByPurpose(EtcdCA).KeyPair; kp != nil {
+		t.Fatal("Generate must not self-sign a substitute etcd CA")
+	}
+}
+
+// TestEnsureRotatedReissuesDependentLeaf guards against regressing the fix for chunk0-2:
+// rotating the etcd CA must reissue the APIServerEtcdClient leaf signed by it, so the leaf
+// never keeps chaining to the old signer after pruneExpiredCerts eventually drops it.
+func TestEnsureRotatedReissuesDependentLeaf(t *testing.T) {
+	etcdCA := &Certificate{Purpose: EtcdCA, KeyConfig: KeyConfig{CAValidity: 2 * time.Minute}}
+	oldKP, err := generateCACert(etcdCA.KeyConfig)
+	if err != nil {
+		t.Fatalf("generateCACert: %v", err)
+	}
+	etcdCA.KeyPair = oldKP
+	if etcdCA.NotAfter, err = certNotAfter(oldKP.Cert); err != nil {
+		t.Fatalf("certNotAfter: %v", err)
+	}
+	if !etcdCA.needsRotation() {
+		t.Fatal("test CA should already be past its refresh window")
+	}
+
+	leaf := &Certificate{Purpose: APIServerEtcdClient}
+	if err := leaf.GenerateSignedBy(oldKP); err != nil {
+		t.Fatalf("GenerateSignedBy: %v", err)
+	}
+
+	allCerts := Certificates{etcdCA, leaf}
+	clusterName := client.ObjectKey{Namespace: "default", Name: "test-cluster"}
+	owner := metav1.OwnerReference{Name: "test-cluster", Kind: "Cluster"}
+
+	sch := runtime.NewScheme()
+	if err := scheme.AddToScheme(sch); err != nil {
+		t.Fatalf("AddToScheme: %v", err)
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(sch).
+		WithObjects(etcdCA.AsSecret(clusterName, owner), leaf.AsSecret(clusterName, owner)).
+		Build()
+
+	if err := allCerts.EnsureRotated(context.Background(), fakeClient, clusterName, owner); err != nil {
+		t.Fatalf("EnsureRotated: %v", err)
+	}
+
+	if string(etcdCA.KeyPair.Cert) == string(oldKP.Cert) {
+		t.Fatal("EnsureRotated did not rotate the etcd CA")
+	}
+
+	leafCerts, err := cert.ParseCertsPEM(leaf.KeyPair.Cert)
+	if err != nil || len(leafCerts) == 0 {
+		t.Fatalf("unable to parse reissued leaf certificate: %v", err)
+	}
+	if err := allCerts.verifyLeafChainsToCA(APIServerEtcdClient, leafCerts[0]); err != nil {
+		t.Fatalf("reissued leaf does not chain to the new CA: %v", err)
+	}
+
+	oldCACerts, err := cert.ParseCertsPEM(oldKP.Cert)
+	if err != nil || len(oldCACerts) == 0 {
+		t.Fatalf("unable to parse old CA certificate: %v", err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(oldCACerts[0])
+	if _, err := leafCerts[0].Verify(x509.VerifyOptions{Roots: pool, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny}}); err == nil {
+		t.Fatal("reissued leaf still chains to the old, rotated-away CA")
+	}
+}
+
+// countingReader counts Get calls and how many of them missed (NotFound), so
+// BenchmarkLookup and BenchmarkLookupCached can report how much of each Lookup's cost was a
+// real, unsatisfied read versus one served from already-resident data.
+type countingReader struct {
+	client.Reader
+	gets, misses int
+}
+
+func (r *countingReader) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	r.gets++
+	err := r.Reader.Get(ctx, key, obj, opts...)
+	if apierrors.IsNotFound(err) {
+		r.misses++
+	}
+	return err
+}
+
+// BenchmarkLookup exercises Lookup against a reader with no Secrets present, standing in
+// for a lookup with no warm cache behind it: every certificate misses.
+func BenchmarkLookup(b *testing.B) {
+	clusterName := client.ObjectKey{Namespace: "default", Name: "bench-cluster"}
+	sch := runtime.NewScheme()
+	if err := scheme.AddToScheme(sch); err != nil {
+		b.Fatalf("AddToScheme: %v", err)
+	}
+	reader := &countingReader{Reader: fake.NewClientBuilder().WithScheme(sch).Build()}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		fresh := NewCertificatesForInitialControlPlane()
+		if err := fresh.Lookup(context.Background(), reader, clusterName); err != nil {
+			b.Fatalf("Lookup: %v", err)
+		}
+	}
+	b.ReportMetric(float64(reader.misses)/float64(b.N), "misses/op")
+}
+
+// BenchmarkLookupCached exercises LookupCached against a reader pre-seeded with every
+// Secret it will look up, standing in for a warm informer cache: every certificate hits,
+// dropping misses/op to zero next to BenchmarkLookup's N.
+func BenchmarkLookupCached(b *testing.B) {
+	clusterName := client.ObjectKey{Namespace: "default", Name: "bench-cluster"}
+	seedCerts := NewCertificatesForInitialControlPlane()
+
+	sch := runtime.NewScheme()
+	if err := scheme.AddToScheme(sch); err != nil {
+		b.Fatalf("AddToScheme: %v", err)
+	}
+	builder := fake.NewClientBuilder().WithScheme(sch)
+	for _, certificate := range seedCerts {
+		kp, err := generateCACert(KeyConfig{})
+		if err != nil {
+			b.Fatalf("generateCACert: %v", err)
+		}
+		builder = builder.WithObjects(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      Name(clusterName.Name, certificate.Purpose),
+				Namespace: clusterName.Namespace,
+			},
+			Data: map[string][]byte{
+				TLSCrtDataName: kp.Cert,
+				TLSKeyDataName: kp.Key,
+			},
+		})
+		if isCAPurpose(certificate.Purpose) {
+			bundleKey := caBundleKey(clusterName, certificate.Purpose)
+			builder = builder.WithObjects(&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: bundleKey.Name, Namespace: bundleKey.Namespace},
+				Data:       map[string][]byte{caBundleDataName: kp.Cert},
+			})
+		}
+	}
+	reader := &countingReader{Reader: builder.Build()}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		fresh := NewCertificatesForInitialControlPlane()
+		if err := fresh.LookupCached(context.Background(), reader, clusterName); err != nil {
+			b.Fatalf("LookupCached: %v", err)
+		}
+	}
+	b.ReportMetric(float64(reader.misses)/float64(b.N), "misses/op")
+}